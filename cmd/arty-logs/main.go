@@ -0,0 +1,151 @@
+// Command arty-logs merges the log table of one or more piped-in SQLite
+// databases into a single time-ordered view, turning the log table that
+// every arty- component writes to into a first-class observability
+// surface across the pipeline.
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Artificial-Polyglot/io-lib/dbio"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, "arty-logs:", err)
+		os.Exit(1)
+	}
+}
+
+// run does the actual work and returns any error instead of exiting
+// directly, so main can still run deferred cleanup (closing databases,
+// removing the stdin temp file) before the process exits.
+func run() error {
+	var (
+		since     = flag.String("since", "", "only include entries at or after this time (RFC3339)")
+		until     = flag.String("until", "", "only include entries at or before this time (RFC3339)")
+		component = flag.String("component", "", "only include entries from this component")
+		level     = flag.String("level", "", "only include entries at this level")
+		grep      = flag.String("grep", "", "only include entries whose message contains this substring")
+		format    = flag.String("format", "text", "output format: text, json, or sqlite")
+	)
+	var inputs stringList
+	flag.Var(&inputs, "in", "path to a piped database to merge (repeatable); defaults to stdin if omitted")
+	flag.Parse()
+
+	filters, err := parseFilters(*since, *until, *component, *level, *grep)
+	if err != nil {
+		return err
+	}
+
+	dbs, cleanup, err := openInputs(inputs)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	merger := dbio.NewLogMerger(filters)
+	entries, err := merger.Merge(dbs)
+	if err != nil {
+		return err
+	}
+
+	switch *format {
+	case "text":
+		writeText(os.Stdout, entries)
+		return nil
+	case "json":
+		if err := json.NewEncoder(os.Stdout).Encode(entries); err != nil {
+			return fmt.Errorf("encoding json: %w", err)
+		}
+		return nil
+	case "sqlite":
+		if err := merger.WriteSQLite(entries, dbs, os.Stdout); err != nil {
+			return fmt.Errorf("writing merged sqlite output: %w", err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown --format %q (want text, json, or sqlite)", *format)
+	}
+}
+
+func parseFilters(since, until, component, level, grep string) (dbio.LogMergeFilters, error) {
+	f := dbio.LogMergeFilters{Component: component, Level: level, Grep: grep}
+	if since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			return f, fmt.Errorf("parsing --since: %w", err)
+		}
+		f.Since = t
+	}
+	if until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			return f, fmt.Errorf("parsing --until: %w", err)
+		}
+		f.Until = t
+	}
+	return f, nil
+}
+
+// openInputs opens every --in path, or falls back to reading a single
+// piped database from stdin if none were given (repeated --in exists
+// precisely because stdin can only carry one). The returned cleanup func
+// closes every opened database and removes any temp file created for
+// stdin; it is always safe to call, even after a partial failure.
+func openInputs(paths []string) ([]dbio.NamedDB, func(), error) {
+	var dbs []dbio.NamedDB
+	var closers []func()
+	cleanup := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	if len(paths) == 0 {
+		db, dbPath, err := dbio.OpenDB()
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("reading db from stdin: %w", err)
+		}
+		dbs = append(dbs, dbio.NamedDB{Name: "stdin", DB: db})
+		closers = append(closers, func() { dbio.CloseDB(db, dbPath) })
+		return dbs, cleanup, nil
+	}
+
+	for _, path := range paths {
+		db, err := sql.Open("sqlite3", path)
+		if err != nil {
+			return dbs, cleanup, fmt.Errorf("opening %s: %w", path, err)
+		}
+		if err := db.Ping(); err != nil {
+			db.Close()
+			return dbs, cleanup, fmt.Errorf("opening %s: %w", path, err)
+		}
+		closers = append(closers, func() { db.Close() })
+		dbs = append(dbs, dbio.NamedDB{Name: path, DB: db})
+	}
+	return dbs, cleanup, nil
+}
+
+func writeText(w *os.File, entries []dbio.MergedEntry) {
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s [%s] %s (%s): %s\n", e.CreatedAt, e.Level, e.Component, e.Source, e.Message)
+	}
+}
+
+// stringList implements flag.Value to collect repeated --in flags.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(v string) error {
+	*s = append(*s, v)
+	return nil
+}
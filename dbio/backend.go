@@ -0,0 +1,99 @@
+package dbio
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Handle is backend-specific state returned by Backend.Open and consumed
+// by Backend.Write to locate whatever Open materialized: a temp file path
+// for SQLiteBackend, a schema name for PostgresBackend.
+type Handle any
+
+// Backend is the storage engine a component actually talks to, behind the
+// Unix-pipe contract: Open always receives the incoming pipe (or an empty
+// reader, if stdin is a terminal) as a serialized SQLite stream, and Write
+// always produces that same serialized SQLite stream on the outgoing
+// pipe, regardless of what the component used internally. This lets a
+// component optionally run against Postgres or another backend while
+// still speaking SQLite on the pipe.
+type Backend interface {
+	// Open materializes r - a serialized SQLite database, empty if
+	// nothing was piped in - and returns a *sql.DB ready for the
+	// component to use, plus a Handle Write needs to flush it back out.
+	Open(r io.Reader) (*sql.DB, Handle, error)
+
+	// Write flushes db back out through w as a serialized SQLite stream,
+	// using handle to locate whatever Open materialized.
+	Write(db *sql.DB, handle Handle, w io.Writer) error
+
+	// Dialect returns the SQL dialect this backend's *sql.DB speaks, for
+	// schema and migration DDL that must be generated per backend.
+	Dialect() Dialect
+}
+
+// defaultBackend is what OpenDB/OutputDB use: the pipe's SQLite stream
+// copied straight to a temp file and queried in place.
+var defaultBackend Backend = SQLiteBackend{}
+
+// SQLiteBackend is the default Backend and preserves today's behavior
+// exactly: no translation, the pipe's SQLite stream is the database.
+type SQLiteBackend struct{}
+
+// Open implements Backend.
+func (SQLiteBackend) Open(r io.Reader) (*sql.DB, Handle, error) {
+	db, dbPath, err := readDB(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, dbPath, nil
+}
+
+// Write implements Backend.
+func (SQLiteBackend) Write(db *sql.DB, handle Handle, w io.Writer) error {
+	dbPath, ok := handle.(string)
+	if !ok {
+		db.Close()
+		return fmt.Errorf("sqlite backend: unexpected handle %T, want string", handle)
+	}
+	return writeDB(db, dbPath, w)
+}
+
+// Dialect implements Backend.
+func (SQLiteBackend) Dialect() Dialect { return sqliteDialect{} }
+
+// OpenWithBackend is like OpenDB but lets a component choose its storage
+// backend alongside its migrations. OpenDB is equivalent to
+// OpenWithBackend(SQLiteBackend{}, baseMigrations) with the handle
+// narrowed back to a file path for source compatibility.
+func OpenWithBackend(backend Backend, migrations []Migration) (*sql.DB, Handle, error) {
+	var r io.Reader = os.Stdin
+	if isTerminal(os.Stdin) {
+		r = strings.NewReader("")
+	}
+
+	db, handle, err := backend.Open(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := NewMigrator(db, migrations, backend.Dialect()).Apply(); err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return db, handle, nil
+}
+
+// OutputWithBackend is like OutputDB but for an arbitrary backend. It
+// closes db in all cases; the caller is not expected to call CloseDB too.
+func OutputWithBackend(backend Backend, db *sql.DB, handle Handle) error {
+	if isTerminal(os.Stdout) {
+		fmt.Fprintln(os.Stderr, "stdout is a terminal, skipping database output")
+		fmt.Fprintln(os.Stderr, "pipe to a file or another component to capture the database")
+		db.Close()
+		return nil
+	}
+	return backend.Write(db, handle, os.Stdout)
+}
@@ -10,17 +10,27 @@ import (
 )
 
 // OpenDB reads a serialized sqlite database from stdin (if piped) or creates
-// a fresh one, ensures the standard schema exists, and returns an open *sql.DB
-// along with the temp file path. The caller must call OutputDB or CloseDB when done.
+// a fresh one, applies the standard schema migrations, and returns an open
+// *sql.DB along with the temp file path. The caller must call OutputDB or
+// CloseDB when done.
 func OpenDB() (*sql.DB, string, error) {
-	db, dbPath, err := readDB(os.Stdin, isTerminal(os.Stdin))
+	return OpenDBWithMigrations(baseMigrations)
+}
+
+// OpenDBWithMigrations is like OpenDB but applies the given migrations
+// instead of the built-in schema. This lets a component carry its own
+// schema additions (e.g. extra scoring columns on words) while still
+// accepting older piped-in databases, since already-applied migrations
+// are skipped.
+func OpenDBWithMigrations(migrations []Migration) (*sql.DB, string, error) {
+	db, handle, err := OpenWithBackend(defaultBackend, migrations)
 	if err != nil {
 		return nil, "", err
 	}
-	if err = createSchema(db); err != nil {
+	dbPath, ok := handle.(string)
+	if !ok {
 		db.Close()
-		os.Remove(dbPath)
-		return nil, "", err
+		return nil, "", fmt.Errorf("default backend returned unexpected handle %T", handle)
 	}
 	return db, dbPath, nil
 }
@@ -30,15 +40,7 @@ func OpenDB() (*sql.DB, string, error) {
 // temp file in all cases.
 func OutputDB(db *sql.DB, dbPath string) error {
 	defer os.Remove(dbPath)
-
-	if isTerminal(os.Stdout) {
-		fmt.Fprintln(os.Stderr, "stdout is a terminal, skipping database output")
-		fmt.Fprintln(os.Stderr, "pipe to a file or another component to capture the database")
-		db.Close()
-		return nil
-	}
-
-	return writeDB(db, dbPath, os.Stdout)
+	return OutputWithBackend(defaultBackend, db, dbPath)
 }
 
 // CloseDB closes the database and removes the temp file without writing output.
@@ -55,20 +57,17 @@ func isTerminal(f *os.File) bool {
 	return (stat.Mode() & os.ModeCharDevice) != 0
 }
 
-func readDB(r io.Reader, terminal bool) (*sql.DB, string, error) {
+func readDB(r io.Reader) (*sql.DB, string, error) {
 	tmpFile, err := os.CreateTemp("", "arty-*.db")
 	if err != nil {
 		return nil, "", fmt.Errorf("creating temp db: %w", err)
 	}
 	tmpPath := tmpFile.Name()
 
-	if !terminal {
-		_, err = io.Copy(tmpFile, r)
-		if err != nil {
-			tmpFile.Close()
-			os.Remove(tmpPath)
-			return nil, "", fmt.Errorf("reading db from stdin: %w", err)
-		}
+	if _, err := io.Copy(tmpFile, r); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return nil, "", fmt.Errorf("reading db from stdin: %w", err)
 	}
 	tmpFile.Close()
 
@@ -105,11 +104,51 @@ func writeDB(db *sql.DB, dbPath string, w io.Writer) error {
 	return nil
 }
 
-func createSchema(db *sql.DB) error {
-	stmts := []string{
-		`PRAGMA temp_store = MEMORY`,
-		`CREATE TABLE IF NOT EXISTS ident (
-			dataset_id INTEGER PRIMARY KEY AUTOINCREMENT,
+// baseMigrations is the schema every component applies by default. New
+// fields or tables should be added as additional, later migrations here
+// rather than by editing "0001_initial", so that databases piped in from
+// older components keep migrating forward cleanly.
+var baseMigrations = schemaMigrations(sqliteDialect{})
+
+// schemaMigrations builds the standard ident/scripts/words/log schema as a
+// single "0001_initial" migration, generating its DDL for the given
+// dialect so the same schema can be provisioned on a backend other than
+// SQLite (see PostgresBackend).
+func schemaMigrations(d Dialect) []Migration {
+	return []Migration{
+		{
+			ID: "0001_initial",
+			Up: func(tx *sql.Tx) error {
+				for _, stmt := range schemaDDL(d) {
+					if _, err := tx.Exec(stmt); err != nil {
+						return fmt.Errorf("schema exec %q: %w", stmt[:min(len(stmt), 60)], err)
+					}
+				}
+				return nil
+			},
+			Down: func(tx *sql.Tx) error {
+				for _, table := range []string{"words", "scripts", "log", "ident"} {
+					if _, err := tx.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s", table)); err != nil {
+						return fmt.Errorf("dropping table %s: %w", table, err)
+					}
+				}
+				return nil
+			},
+		},
+	}
+}
+
+// schemaDDL generates the CREATE TABLE/INDEX statements for the standard
+// schema, substituting the auto-increment column, table suffix, and "now"
+// default for the given dialect.
+func schemaDDL(d Dialect) []string {
+	pk := d.AutoIncrementColumn()
+	suffix := d.TableSuffix()
+
+	stmts := append([]string{}, d.InitStatements()...)
+	stmts = append(stmts,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS ident (
+			dataset_id %s,
 			bible_id TEXT NOT NULL,
 			audio_OT_id TEXT NOT NULL,
 			audio_NT_id TEXT NOT NULL,
@@ -123,10 +162,10 @@ func createSchema(db *sql.DB) error {
 			rolv_id INTEGER NOT NULL,
 			alphabet TEXT NOT NULL,
 			language_name TEXT NOT NULL,
-			version_name TEXT NOT NULL) STRICT`,
+			version_name TEXT NOT NULL)%s`, pk, suffix),
 		`CREATE UNIQUE INDEX IF NOT EXISTS ident_bible_idx ON ident (bible_id)`,
-		`CREATE TABLE IF NOT EXISTS scripts (
-			script_id INTEGER PRIMARY KEY AUTOINCREMENT,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS scripts (
+			script_id %s,
 			dataset_id INTEGER NOT NULL,
 			book_id TEXT NOT NULL,
 			chapter_num INTEGER NOT NULL,
@@ -144,13 +183,13 @@ func createSchema(db *sql.DB) error {
 			script_begin_ts REAL NOT NULL DEFAULT 0.0,
 			script_end_ts REAL NOT NULL DEFAULT 0.0,
 			fa_score REAL NOT NULL DEFAULT 0.0,
-			FOREIGN KEY(dataset_id) REFERENCES ident(dataset_id)) STRICT`,
+			FOREIGN KEY(dataset_id) REFERENCES ident(dataset_id))%s`, pk, suffix),
 		`CREATE UNIQUE INDEX IF NOT EXISTS scripts_idx
 			ON scripts (book_id, chapter_num, verse_str)`,
 		`CREATE INDEX IF NOT EXISTS script_num_idx ON scripts (script_num)`,
 		`CREATE INDEX IF NOT EXISTS scripts_file_idx ON scripts (audio_file)`,
-		`CREATE TABLE IF NOT EXISTS words (
-			word_id INTEGER PRIMARY KEY AUTOINCREMENT,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS words (
+			word_id %s,
 			script_id INTEGER NOT NULL,
 			word_seq INTEGER NOT NULL,
 			verse_num INTEGER NOT NULL,
@@ -164,20 +203,15 @@ func createSchema(db *sql.DB) error {
 			src_word_enc TEXT NOT NULL DEFAULT '',
 			word_multi_enc TEXT NOT NULL DEFAULT '',
 			src_word_multi_enc TEXT NOT NULL DEFAULT '',
-			FOREIGN KEY(script_id) REFERENCES scripts(script_id)) STRICT`,
+			FOREIGN KEY(script_id) REFERENCES scripts(script_id))%s`, pk, suffix),
 		`CREATE UNIQUE INDEX IF NOT EXISTS words_idx
 			ON words (script_id, word_seq)`,
-		`CREATE TABLE IF NOT EXISTS log (
-			log_id INTEGER PRIMARY KEY AUTOINCREMENT,
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS log (
+			log_id %s,
 			component TEXT NOT NULL,
 			level TEXT NOT NULL,
 			message TEXT NOT NULL,
-			created_at TEXT NOT NULL DEFAULT (datetime('now'))) STRICT`,
-	}
-	for _, stmt := range stmts {
-		if _, err := db.Exec(stmt); err != nil {
-			return fmt.Errorf("schema exec %q: %w", stmt[:min(len(stmt), 60)], err)
-		}
-	}
-	return nil
+			created_at TEXT NOT NULL DEFAULT %s)%s`, pk, d.NowExpr(), suffix),
+	)
+	return stmts
 }
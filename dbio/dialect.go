@@ -0,0 +1,51 @@
+package dbio
+
+import "fmt"
+
+// Dialect abstracts the handful of places schema and migration DDL differ
+// between backends, so the same migrations can generate either SQLite or
+// Postgres DDL.
+type Dialect interface {
+	// Name identifies the dialect, e.g. "sqlite" or "postgres".
+	Name() string
+
+	// AutoIncrementColumn returns the column definition for an
+	// auto-incrementing primary key, e.g. "INTEGER PRIMARY KEY
+	// AUTOINCREMENT" for SQLite or "SERIAL PRIMARY KEY" for Postgres.
+	AutoIncrementColumn() string
+
+	// TableSuffix returns any suffix appended after a CREATE TABLE's
+	// closing paren, e.g. " STRICT" for SQLite, "" for Postgres.
+	TableSuffix() string
+
+	// NowExpr returns the SQL expression for "the current time", used as
+	// a column default.
+	NowExpr() string
+
+	// InitStatements returns dialect-specific statements to run once
+	// before any schema DDL (e.g. SQLite's PRAGMA temp_store).
+	InitStatements() []string
+
+	// Placeholder returns the bind parameter marker for the i'th
+	// argument (1-indexed) of a query, e.g. "?" for SQLite or "$1", "$2"
+	// for Postgres.
+	Placeholder(i int) string
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string                { return "sqlite" }
+func (sqliteDialect) AutoIncrementColumn() string { return "INTEGER PRIMARY KEY AUTOINCREMENT" }
+func (sqliteDialect) TableSuffix() string         { return " STRICT" }
+func (sqliteDialect) NowExpr() string             { return "(datetime('now'))" }
+func (sqliteDialect) InitStatements() []string    { return []string{`PRAGMA temp_store = MEMORY`} }
+func (sqliteDialect) Placeholder(i int) string    { return "?" }
+
+type postgresDialect struct{}
+
+func (postgresDialect) Name() string                { return "postgres" }
+func (postgresDialect) AutoIncrementColumn() string { return "SERIAL PRIMARY KEY" }
+func (postgresDialect) TableSuffix() string         { return "" }
+func (postgresDialect) NowExpr() string             { return "now()" }
+func (postgresDialect) InitStatements() []string    { return nil }
+func (postgresDialect) Placeholder(i int) string    { return fmt.Sprintf("$%d", i) }
@@ -0,0 +1,140 @@
+//go:build fts5
+
+package dbio
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// EnableFTS creates a contentless FTS5 virtual table mirroring
+// scripts.script_text (and, if opts.IndexWords is set, words.word and
+// words.uroman), installs triggers to keep it in sync with scripts/words,
+// and backfills it from any rows already present. Search then queries the
+// FTS5 table rather than scanning script_text with LIKE.
+//
+// Requires building with the fts5 build tag (-tags fts5), since FTS5 is a
+// compile-time option of github.com/mattn/go-sqlite3.
+func EnableFTS(db *sql.DB, opts FTSOptions) error {
+	if opts.Tokenizer == "" {
+		opts.Tokenizer = TokenizerUnicode61
+	}
+
+	if err := enableScriptsFTS(db, opts.Tokenizer); err != nil {
+		return err
+	}
+	if opts.IndexWords {
+		if err := enableWordsFTS(db, opts.Tokenizer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func enableScriptsFTS(db *sql.DB, tok Tokenizer) error {
+	stmts := []string{
+		fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS scripts_fts USING fts5(
+			script_text, content='', tokenize='%s')`, tok),
+		`CREATE TRIGGER IF NOT EXISTS scripts_fts_ai AFTER INSERT ON scripts BEGIN
+			INSERT INTO scripts_fts(rowid, script_text) VALUES (new.script_id, new.script_text);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS scripts_fts_ad AFTER DELETE ON scripts BEGIN
+			INSERT INTO scripts_fts(scripts_fts, rowid, script_text) VALUES ('delete', old.script_id, old.script_text);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS scripts_fts_au AFTER UPDATE ON scripts BEGIN
+			INSERT INTO scripts_fts(scripts_fts, rowid, script_text) VALUES ('delete', old.script_id, old.script_text);
+			INSERT INTO scripts_fts(rowid, script_text) VALUES (new.script_id, new.script_text);
+		END`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("enabling scripts fts: %w", err)
+		}
+	}
+	return backfillFTS(db, "scripts_fts",
+		`INSERT INTO scripts_fts(rowid, script_text) SELECT script_id, script_text FROM scripts`)
+}
+
+func enableWordsFTS(db *sql.DB, tok Tokenizer) error {
+	stmts := []string{
+		fmt.Sprintf(`CREATE VIRTUAL TABLE IF NOT EXISTS words_fts USING fts5(
+			word, uroman, content='', tokenize='%s')`, tok),
+		`CREATE TRIGGER IF NOT EXISTS words_fts_ai AFTER INSERT ON words BEGIN
+			INSERT INTO words_fts(rowid, word, uroman) VALUES (new.word_id, new.word, new.uroman);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS words_fts_ad AFTER DELETE ON words BEGIN
+			INSERT INTO words_fts(words_fts, rowid, word, uroman) VALUES ('delete', old.word_id, old.word, old.uroman);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS words_fts_au AFTER UPDATE ON words BEGIN
+			INSERT INTO words_fts(words_fts, rowid, word, uroman) VALUES ('delete', old.word_id, old.word, old.uroman);
+			INSERT INTO words_fts(rowid, word, uroman) VALUES (new.word_id, new.word, new.uroman);
+		END`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("enabling words fts: %w", err)
+		}
+	}
+	return backfillFTS(db, "words_fts",
+		`INSERT INTO words_fts(rowid, word, uroman) SELECT word_id, word, uroman FROM words`)
+}
+
+// backfillFTS populates a freshly created contentless FTS5 table from its
+// source table, but only the first time: re-running EnableFTS against a
+// database that already has rows must not duplicate them.
+func backfillFTS(db *sql.DB, table, insertSQL string) error {
+	var count int
+	if err := db.QueryRow(fmt.Sprintf("SELECT count(*) FROM %s", table)).Scan(&count); err != nil {
+		return fmt.Errorf("checking %s backfill: %w", table, err)
+	}
+	if count > 0 {
+		return nil
+	}
+	if _, err := db.Exec(insertSQL); err != nil {
+		return fmt.Errorf("backfilling %s: %w", table, err)
+	}
+	return nil
+}
+
+// Search runs a BM25-ranked full-text query against scripts.script_text,
+// returning snippets with the match highlighted. filters narrows by
+// dataset/book/chapter when any of its fields are non-zero.
+func Search(db *sql.DB, query string, filters SearchFilters) ([]Hit, error) {
+	sqlStr := `SELECT scripts.script_id, scripts.book_id, scripts.chapter_num, scripts.verse_str,
+			snippet(scripts_fts, 0, '[', ']', '...', 10) AS snippet,
+			bm25(scripts_fts) AS rank
+		FROM scripts_fts
+		JOIN scripts ON scripts.script_id = scripts_fts.rowid
+		WHERE scripts_fts MATCH ?`
+	args := []any{query}
+
+	if filters.DatasetID != 0 {
+		sqlStr += " AND scripts.dataset_id = ?"
+		args = append(args, filters.DatasetID)
+	}
+	if filters.BookID != "" {
+		sqlStr += " AND scripts.book_id = ?"
+		args = append(args, filters.BookID)
+	}
+	if filters.ChapterNum != 0 {
+		sqlStr += " AND scripts.chapter_num = ?"
+		args = append(args, filters.ChapterNum)
+	}
+	sqlStr += " ORDER BY rank"
+
+	rows, err := db.Query(sqlStr, args...)
+	if err != nil {
+		return nil, fmt.Errorf("searching scripts_fts: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []Hit
+	for rows.Next() {
+		var h Hit
+		if err := rows.Scan(&h.ScriptID, &h.BookID, &h.ChapterNum, &h.VerseStr, &h.Snippet, &h.Rank); err != nil {
+			return nil, fmt.Errorf("scanning search result: %w", err)
+		}
+		hits = append(hits, h)
+	}
+	return hits, rows.Err()
+}
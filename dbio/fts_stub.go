@@ -0,0 +1,23 @@
+//go:build !fts5
+
+package dbio
+
+import (
+	"database/sql"
+	"errors"
+)
+
+// errFTS5NotBuilt is returned by EnableFTS and Search when this binary was
+// built without the fts5 build tag, since FTS5 support is a compile-time
+// option of github.com/mattn/go-sqlite3.
+var errFTS5NotBuilt = errors.New("dbio: full-text search requires building with -tags fts5")
+
+// EnableFTS is unavailable in this build. Rebuild with -tags fts5.
+func EnableFTS(db *sql.DB, opts FTSOptions) error {
+	return errFTS5NotBuilt
+}
+
+// Search is unavailable in this build. Rebuild with -tags fts5.
+func Search(db *sql.DB, query string, filters SearchFilters) ([]Hit, error) {
+	return nil, errFTS5NotBuilt
+}
@@ -0,0 +1,43 @@
+package dbio
+
+// Tokenizer selects the FTS5 tokenizer used when indexing scripts.
+type Tokenizer string
+
+const (
+	// TokenizerUnicode61 is the default: good general-purpose word
+	// splitting with diacritics folded so accented and unaccented forms
+	// of a word match each other.
+	TokenizerUnicode61 Tokenizer = "unicode61 remove_diacritics 2"
+
+	// TokenizerTrigram indexes overlapping 3-character sequences instead
+	// of words, which works better for scripts without clear word
+	// boundaries (e.g. many non-Latin scripts).
+	TokenizerTrigram Tokenizer = "trigram"
+)
+
+// FTSOptions configures EnableFTS.
+type FTSOptions struct {
+	// Tokenizer selects the FTS5 tokenizer. Defaults to TokenizerUnicode61.
+	Tokenizer Tokenizer
+
+	// IndexWords additionally indexes words.word and words.uroman, for
+	// components that need to search at the word rather than verse level.
+	IndexWords bool
+}
+
+// SearchFilters narrows Search to a subset of scripts.
+type SearchFilters struct {
+	DatasetID  int64  // 0 matches any dataset
+	BookID     string // "" matches any book
+	ChapterNum int    // 0 matches any chapter
+}
+
+// Hit is a single full-text search result, ranked by BM25.
+type Hit struct {
+	ScriptID   int64
+	BookID     string
+	ChapterNum int
+	VerseStr   string
+	Snippet    string
+	Rank       float64
+}
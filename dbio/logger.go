@@ -1,22 +1,50 @@
 package dbio
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// sqliteTimeFormat matches the layout SQLite's datetime('now') produces,
+// so created_at values recorded via insertLog and rows read back for
+// reconciliation compare the same way.
+const sqliteTimeFormat = "2006-01-02 15:04:05"
+
 // Logger writes log entries to both a database table and stderr.
 // INFO and WARN go to the database only. ERROR goes to both.
 type Logger struct {
 	db        *sql.DB
 	component string
+	fields    map[string]any
+	reg       *subRegistry
 }
 
 // NewLogger creates a logger that tags entries with the given component name.
 func NewLogger(db *sql.DB, component string) *Logger {
-	return &Logger{db: db, component: component}
+	return &Logger{db: db, component: component, reg: &subRegistry{}}
+}
+
+// WithFields returns a child logger that serializes the given fields as
+// logfmt (key=value pairs) into every message it writes, so downstream
+// tools can parse structured attributes out of the log table's message
+// column. The child shares the parent's subscriptions, so a Subscribe or
+// Tail call made on the parent also observes entries logged through it.
+func (l *Logger) WithFields(fields map[string]any) *Logger {
+	merged := make(map[string]any, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return &Logger{db: l.db, component: l.component, fields: merged, reg: l.reg}
 }
 
 // Info logs an informational message to the database.
@@ -45,16 +73,30 @@ func (l *Logger) Fatal(args ...any) {
 }
 
 func (l *Logger) insertLog(level string, message string) {
-	if l.db == nil {
-		return
+	if len(l.fields) > 0 {
+		message = message + " " + formatLogfmt(l.fields)
 	}
-	_, err := l.db.Exec(
-		`INSERT INTO log (component, level, message) VALUES (?, ?, ?)`,
-		l.component, level, message,
-	)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "WARN [%s]: failed to write log to db: %v\n", l.component, err)
+
+	entry := LogEntry{
+		Component: l.component,
+		Level:     level,
+		Message:   message,
+		CreatedAt: time.Now().UTC().Format(sqliteTimeFormat),
 	}
+
+	if l.db != nil {
+		res, err := l.db.Exec(
+			`INSERT INTO log (component, level, message, created_at) VALUES (?, ?, ?, ?)`,
+			l.component, level, message, entry.CreatedAt,
+		)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARN [%s]: failed to write log to db: %v\n", l.component, err)
+		} else if id, idErr := res.LastInsertId(); idErr == nil {
+			entry.ID = id
+		}
+	}
+
+	l.publish(entry)
 }
 
 func joinArgs(args []any) string {
@@ -64,3 +106,234 @@ func joinArgs(args []any) string {
 	}
 	return strings.Join(parts, " ")
 }
+
+func formatLogfmt(fields map[string]any) string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%s", k, logfmtValue(fields[k]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func logfmtValue(v any) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " =\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// LogEntry is a single row of the log table, whether read back historically
+// or delivered live through Subscribe/Tail.
+type LogEntry struct {
+	ID        int64
+	Component string
+	Level     string
+	Message   string
+	CreatedAt string
+}
+
+// LogFilter selects which log entries a subscription receives. The zero
+// value matches everything. Since, if set, also bounds the historical
+// reconciliation query Subscribe runs on connect.
+type LogFilter struct {
+	Component string
+	Level     string
+	Since     time.Time
+}
+
+func (f LogFilter) match(e LogEntry) bool {
+	if f.Component != "" && f.Component != e.Component {
+		return false
+	}
+	if f.Level != "" && f.Level != e.Level {
+		return false
+	}
+	if !f.Since.IsZero() {
+		createdAt, err := time.Parse(sqliteTimeFormat, e.CreatedAt)
+		if err == nil && createdAt.Before(f.Since) {
+			return false
+		}
+	}
+	return true
+}
+
+// subRegistry is the mutable subscriber state shared by a Logger and every
+// child produced from it via WithFields, so a subscription set up on one
+// observes entries logged through any of them.
+type subRegistry struct {
+	mu        sync.Mutex
+	subs      map[int]*subscriber
+	nextSubID int
+}
+
+type subscriber struct {
+	filter LogFilter
+	ch     chan LogEntry
+	// watermark is the highest log_id the reconciliation query for this
+	// subscriber will return. Entries published live with id <= watermark
+	// are guaranteed to already be part of that query's result set (ids
+	// are assigned in commit order), so publish drops them rather than
+	// delivering the same entry twice.
+	watermark int64
+}
+
+// Subscribe registers filter and returns a channel delivering matching
+// LogEntry values as they are logged, along with a func to unregister the
+// subscription. The channel is buffered; a subscriber that falls behind
+// has entries dropped rather than blocking logging for the rest of the
+// pipeline. The returned channel is never closed by Subscribe itself -
+// callers should stop reading from it once the unsubscribe func is called.
+//
+// Subscribe also runs a reconciliation query against the log table so a
+// subscriber created against a piped-in database still sees matching
+// historical rows rather than only rows logged from this point on. To
+// avoid delivering those historical rows a second time through live
+// publish, Subscribe first snapshots the highest matching log_id that
+// already exists and only ever reconciles up to it; any entry logged from
+// that point on is delivered exactly once, live. The snapshot and the
+// subscriber's registration happen under the same reg.mu critical section
+// as publish, so no entry can be published in between: it either commits
+// (and is counted in the snapshot) before the lock is taken, or it blocks
+// on publish's lock until the subscriber is registered and sees it live.
+func (l *Logger) Subscribe(filter LogFilter) (<-chan LogEntry, func()) {
+	ch := make(chan LogEntry, 64)
+
+	l.reg.mu.Lock()
+	var watermark int64
+	if l.db != nil {
+		var err error
+		watermark, err = l.maxMatchingID(filter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "WARN [%s]: subscription watermark query failed: %v\n", l.component, err)
+		}
+	}
+	if l.reg.subs == nil {
+		l.reg.subs = make(map[int]*subscriber)
+	}
+	id := l.reg.nextSubID
+	l.reg.nextSubID++
+	l.reg.subs[id] = &subscriber{filter: filter, ch: ch, watermark: watermark}
+	l.reg.mu.Unlock()
+
+	if l.db != nil {
+		go l.reconcile(filter, ch, watermark)
+	}
+
+	unsubscribe := func() {
+		l.reg.mu.Lock()
+		delete(l.reg.subs, id)
+		l.reg.mu.Unlock()
+	}
+	return ch, unsubscribe
+}
+
+// maxMatchingID returns the highest log_id currently matching filter, or 0
+// if none exist. It is queried before the subscriber is registered so the
+// reconciliation pass it bounds can never overlap with live delivery.
+func (l *Logger) maxMatchingID(filter LogFilter) (int64, error) {
+	query := `SELECT COALESCE(MAX(log_id), 0) FROM log WHERE 1=1`
+	args := filterArgs(filter, &query)
+
+	var max int64
+	if err := l.db.QueryRow(query, args...).Scan(&max); err != nil {
+		return 0, fmt.Errorf("querying subscription watermark: %w", err)
+	}
+	return max, nil
+}
+
+func (l *Logger) reconcile(filter LogFilter, ch chan<- LogEntry, watermark int64) {
+	query := `SELECT log_id, component, level, message, created_at FROM log WHERE log_id <= ?`
+	args := append([]any{watermark}, filterArgs(filter, &query)...)
+	query += " ORDER BY log_id"
+
+	rows, err := l.db.Query(query, args...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "WARN [%s]: subscription reconciliation failed: %v\n", l.component, err)
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var e LogEntry
+		if err := rows.Scan(&e.ID, &e.Component, &e.Level, &e.Message, &e.CreatedAt); err != nil {
+			fmt.Fprintf(os.Stderr, "WARN [%s]: subscription reconciliation scan failed: %v\n", l.component, err)
+			return
+		}
+		ch <- e
+	}
+}
+
+// filterArgs appends filter's conditions to query and returns their bind
+// args, factored out so maxMatchingID's snapshot and reconcile's row fetch
+// always agree on which rows count as "matching".
+func filterArgs(filter LogFilter, query *string) []any {
+	var args []any
+	if filter.Component != "" {
+		*query += " AND component = ?"
+		args = append(args, filter.Component)
+	}
+	if filter.Level != "" {
+		*query += " AND level = ?"
+		args = append(args, filter.Level)
+	}
+	if !filter.Since.IsZero() {
+		*query += " AND created_at >= ?"
+		args = append(args, filter.Since.UTC().Format(sqliteTimeFormat))
+	}
+	return args
+}
+
+func (l *Logger) publish(e LogEntry) {
+	l.reg.mu.Lock()
+	defer l.reg.mu.Unlock()
+
+	for _, sub := range l.reg.subs {
+		if e.ID > 0 && e.ID <= sub.watermark {
+			continue
+		}
+		if !sub.filter.match(e) {
+			continue
+		}
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
+
+// Tail streams log entries matching filter until ctx is cancelled. It is a
+// convenience wrapper around Subscribe for callers that just want a single
+// channel and don't need to call unsubscribe themselves.
+func (l *Logger) Tail(ctx context.Context, filter LogFilter) <-chan LogEntry {
+	in, unsubscribe := l.Subscribe(filter)
+	out := make(chan LogEntry, 64)
+
+	go func() {
+		defer close(out)
+		defer unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-in:
+				if !ok {
+					return
+				}
+				select {
+				case out <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
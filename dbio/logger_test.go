@@ -0,0 +1,128 @@
+package dbio
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openLogDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	if err := NewMigrator(db, schemaMigrations(sqliteDialect{}), sqliteDialect{}).Apply(); err != nil {
+		t.Fatalf("applying schema: %v", err)
+	}
+	return db
+}
+
+func recvWithin(t *testing.T, ch <-chan LogEntry, d time.Duration) (LogEntry, bool) {
+	t.Helper()
+	select {
+	case e, ok := <-ch:
+		return e, ok
+	case <-time.After(d):
+		return LogEntry{}, false
+	}
+}
+
+func TestLoggerSubscribeReconcilesHistoricalRowsExactlyOnce(t *testing.T) {
+	db := openLogDB(t)
+	logger := NewLogger(db, "fa-score")
+
+	logger.Info("before subscribe")
+
+	ch, unsubscribe := logger.Subscribe(LogFilter{})
+	defer unsubscribe()
+
+	e, ok := recvWithin(t, ch, time.Second)
+	if !ok {
+		t.Fatal("expected the pre-existing entry to be delivered via reconciliation")
+	}
+	if e.Message != "before subscribe" {
+		t.Fatalf("got message %q, want %q", e.Message, "before subscribe")
+	}
+
+	logger.Info("after subscribe")
+	e, ok = recvWithin(t, ch, time.Second)
+	if !ok {
+		t.Fatal("expected the post-subscribe entry to be delivered live")
+	}
+	if e.Message != "after subscribe" {
+		t.Fatalf("got message %q, want %q", e.Message, "after subscribe")
+	}
+
+	// No further entries should arrive: in particular the pre-existing
+	// entry must not be delivered a second time.
+	if _, ok := recvWithin(t, ch, 100*time.Millisecond); ok {
+		t.Fatal("received an unexpected extra entry; historical row was likely delivered twice")
+	}
+}
+
+func TestLoggerSubscribeFilter(t *testing.T) {
+	db := openLogDB(t)
+	logger := NewLogger(db, "fa-score")
+
+	logger.Warn("ignored before subscribe")
+
+	ch, unsubscribe := logger.Subscribe(LogFilter{Level: "ERROR"})
+	defer unsubscribe()
+
+	logger.Error("boom")
+	logger.Warn("still ignored")
+
+	e, ok := recvWithin(t, ch, time.Second)
+	if !ok {
+		t.Fatal("expected the matching ERROR entry to be delivered")
+	}
+	if e.Level != "ERROR" || e.Message != "boom" {
+		t.Fatalf("got %+v, want level ERROR message %q", e, "boom")
+	}
+	if _, ok := recvWithin(t, ch, 100*time.Millisecond); ok {
+		t.Fatal("received an entry that should have been excluded by the filter")
+	}
+}
+
+func TestLoggerTailStopsOnContextCancel(t *testing.T) {
+	db := openLogDB(t)
+	logger := NewLogger(db, "fa-score")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := logger.Tail(ctx, LogFilter{})
+
+	logger.Info("live entry")
+	e, ok := recvWithin(t, out, time.Second)
+	if !ok || e.Message != "live entry" {
+		t.Fatalf("got (%+v, %v), want the live entry", e, ok)
+	}
+
+	cancel()
+	if _, ok := recvWithin(t, out, time.Second); ok {
+		t.Fatal("expected Tail's channel to close after context cancellation")
+	}
+}
+
+func TestLoggerWithFieldsSharesSubscriptions(t *testing.T) {
+	db := openLogDB(t)
+	parent := NewLogger(db, "fa-score")
+	child := parent.WithFields(map[string]any{"book": "MRK"})
+
+	ch, unsubscribe := parent.Subscribe(LogFilter{})
+	defer unsubscribe()
+
+	child.Info("chapter done")
+	e, ok := recvWithin(t, ch, time.Second)
+	if !ok {
+		t.Fatal("expected entry logged through child to reach parent's subscription")
+	}
+	if e.Message != `chapter done book=MRK` {
+		t.Fatalf("got message %q, want logfmt fields appended", e.Message)
+	}
+}
@@ -0,0 +1,223 @@
+package dbio
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// NamedDB pairs an open database with a label (typically the input file
+// path, or "stdin") used for provenance when merging.
+type NamedDB struct {
+	Name string
+	DB   *sql.DB
+}
+
+// MergedEntry is a LogEntry annotated with which input database it came
+// from, so a merged view can still be traced back to its source.
+type MergedEntry struct {
+	LogEntry
+	Source string
+}
+
+// LogMergeFilters narrows which rows LogMerger.Merge reads out of each
+// input database. Zero values match anything.
+type LogMergeFilters struct {
+	Since     time.Time
+	Until     time.Time
+	Component string
+	Level     string
+	Grep      string // substring match against message, case-insensitive
+}
+
+// LogMerger reads the log table out of one or more piped-in SQLite
+// databases and merges them into a single time-ordered view, the way
+// arty-logs does for the CLI.
+type LogMerger struct {
+	Filters LogMergeFilters
+}
+
+// NewLogMerger creates a LogMerger applying the given filters to every
+// database it reads.
+func NewLogMerger(filters LogMergeFilters) *LogMerger {
+	return &LogMerger{Filters: filters}
+}
+
+// Merge reads matching rows out of every named database and returns them
+// in created_at order, tagged with their source.
+func (m *LogMerger) Merge(dbs []NamedDB) ([]MergedEntry, error) {
+	var all []MergedEntry
+	for _, named := range dbs {
+		entries, err := m.readOne(named.DB)
+		if err != nil {
+			return nil, fmt.Errorf("reading log from %s: %w", named.Name, err)
+		}
+		for _, e := range entries {
+			all = append(all, MergedEntry{LogEntry: e, Source: named.Name})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		return all[i].CreatedAt < all[j].CreatedAt
+	})
+	return all, nil
+}
+
+func (m *LogMerger) readOne(db *sql.DB) ([]LogEntry, error) {
+	query := `SELECT log_id, component, level, message, created_at FROM log WHERE 1=1`
+	var args []any
+
+	if m.Filters.Component != "" {
+		query += " AND component = ?"
+		args = append(args, m.Filters.Component)
+	}
+	if m.Filters.Level != "" {
+		query += " AND level = ?"
+		args = append(args, m.Filters.Level)
+	}
+	if !m.Filters.Since.IsZero() {
+		query += " AND created_at >= ?"
+		args = append(args, m.Filters.Since.UTC().Format(sqliteTimeFormat))
+	}
+	if !m.Filters.Until.IsZero() {
+		query += " AND created_at <= ?"
+		args = append(args, m.Filters.Until.UTC().Format(sqliteTimeFormat))
+	}
+	query += " ORDER BY log_id"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying log table: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []LogEntry
+	for rows.Next() {
+		var e LogEntry
+		if err := rows.Scan(&e.ID, &e.Component, &e.Level, &e.Message, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning log row: %w", err)
+		}
+		if m.Filters.Grep != "" && !strings.Contains(strings.ToLower(e.Message), strings.ToLower(m.Filters.Grep)) {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// WriteSQLite writes entries, plus any ident rows carried over from dbs,
+// as a fresh SQLite database on w containing only the log table (with an
+// added source column) and ident table, so the merged log can itself be
+// piped into another arty- component.
+func (m *LogMerger) WriteSQLite(entries []MergedEntry, dbs []NamedDB, w io.Writer) error {
+	tmpFile, err := os.CreateTemp("", "arty-*.db")
+	if err != nil {
+		return fmt.Errorf("creating temp db: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpPath)
+
+	db, err := sql.Open("sqlite3", tmpPath)
+	if err != nil {
+		return fmt.Errorf("opening merged output db: %w", err)
+	}
+
+	if err := createMergedLogSchema(db); err != nil {
+		db.Close()
+		return err
+	}
+	for _, e := range entries {
+		if _, err := db.Exec(
+			`INSERT INTO log (component, level, message, created_at, source) VALUES (?, ?, ?, ?, ?)`,
+			e.Component, e.Level, e.Message, e.CreatedAt, e.Source,
+		); err != nil {
+			db.Close()
+			return fmt.Errorf("writing merged log row: %w", err)
+		}
+	}
+	for _, named := range dbs {
+		if err := copyIdentRows(db, named.DB); err != nil {
+			db.Close()
+			return fmt.Errorf("copying ident rows from %s: %w", named.Name, err)
+		}
+	}
+
+	return writeDB(db, tmpPath, w)
+}
+
+func createMergedLogSchema(db *sql.DB) error {
+	stmts := []string{
+		`CREATE TABLE log (
+			log_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			component TEXT NOT NULL,
+			level TEXT NOT NULL,
+			message TEXT NOT NULL,
+			created_at TEXT NOT NULL,
+			source TEXT NOT NULL) STRICT`,
+		`CREATE TABLE ident (
+			dataset_id INTEGER PRIMARY KEY AUTOINCREMENT,
+			bible_id TEXT NOT NULL,
+			audio_OT_id TEXT NOT NULL,
+			audio_NT_id TEXT NOT NULL,
+			text_OT_id TEXT NOT NULL,
+			text_NT_id TEXT NOT NULL,
+			text_source TEXT NOT NULL,
+			language_iso TEXT NOT NULL,
+			asr_language_iso TEXT NOT NULL DEFAULT '',
+			version_code TEXT NOT NULL,
+			language_id INTEGER NOT NULL,
+			rolv_id INTEGER NOT NULL,
+			alphabet TEXT NOT NULL,
+			language_name TEXT NOT NULL,
+			version_name TEXT NOT NULL) STRICT`,
+		`CREATE UNIQUE INDEX ident_bible_idx ON ident (bible_id)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			return fmt.Errorf("creating merged output schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// copyIdentRows carries ident rows over from a source database so the
+// merged log keeps the dataset identity it was produced under. Rows with
+// a bible_id already present (from an earlier source) are skipped.
+func copyIdentRows(dst *sql.DB, src *sql.DB) error {
+	rows, err := src.Query(`SELECT bible_id, audio_OT_id, audio_NT_id, text_OT_id, text_NT_id,
+		text_source, language_iso, asr_language_iso, version_code, language_id, rolv_id,
+		alphabet, language_name, version_name FROM ident`)
+	if err != nil {
+		return fmt.Errorf("reading source ident table: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			bibleID, audioOT, audioNT, textOT, textNT            string
+			textSource, languageISO, asrLanguageISO, versionCode string
+			languageID, rolvID                                   int64
+			alphabet, languageName, versionName                  string
+		)
+		if err := rows.Scan(&bibleID, &audioOT, &audioNT, &textOT, &textNT,
+			&textSource, &languageISO, &asrLanguageISO, &versionCode, &languageID, &rolvID,
+			&alphabet, &languageName, &versionName); err != nil {
+			return fmt.Errorf("scanning source ident row: %w", err)
+		}
+		if _, err := dst.Exec(`INSERT OR IGNORE INTO ident (
+			bible_id, audio_OT_id, audio_NT_id, text_OT_id, text_NT_id, text_source,
+			language_iso, asr_language_iso, version_code, language_id, rolv_id,
+			alphabet, language_name, version_name)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			bibleID, audioOT, audioNT, textOT, textNT, textSource,
+			languageISO, asrLanguageISO, versionCode, languageID, rolvID,
+			alphabet, languageName, versionName); err != nil {
+			return fmt.Errorf("inserting ident row: %w", err)
+		}
+	}
+	return rows.Err()
+}
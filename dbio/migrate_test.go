@@ -0,0 +1,121 @@
+package dbio
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func widgetMigrations() []Migration {
+	return []Migration{
+		{
+			ID: "0001_widgets",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT NOT NULL)`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`DROP TABLE widgets`)
+				return err
+			},
+		},
+		{
+			ID: "0002_widget_color",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE widgets ADD COLUMN color TEXT NOT NULL DEFAULT ''`)
+				return err
+			},
+			Down: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE widgets DROP COLUMN color`)
+				return err
+			},
+		},
+		{
+			ID: "0003_widget_note",
+			Up: func(tx *sql.Tx) error {
+				_, err := tx.Exec(`ALTER TABLE widgets ADD COLUMN note TEXT NOT NULL DEFAULT ''`)
+				return err
+			},
+			Down: nil,
+		},
+	}
+}
+
+func TestMigratorApplyRollbackStatus(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("opening db: %v", err)
+	}
+	defer db.Close()
+
+	all := widgetMigrations()
+	m := NewMigrator(db, all[:2], sqliteDialect{})
+
+	statuses, err := m.Status()
+	if err != nil {
+		t.Fatalf("Status before Apply: %v", err)
+	}
+	for _, s := range statuses {
+		if s.Applied {
+			t.Fatalf("migration %s reported applied before Apply ran", s.ID)
+		}
+	}
+
+	if err := m.Apply(); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO widgets (id, name, color) VALUES (1, 'gizmo', 'red')`); err != nil {
+		t.Fatalf("inserting into migrated table: %v", err)
+	}
+
+	statuses, err = m.Status()
+	if err != nil {
+		t.Fatalf("Status after Apply: %v", err)
+	}
+	if len(statuses) != 2 {
+		t.Fatalf("Status returned %d entries, want 2", len(statuses))
+	}
+	for _, s := range statuses {
+		if !s.Applied {
+			t.Errorf("migration %s not marked applied", s.ID)
+		}
+		if s.AppliedAt == "" {
+			t.Errorf("migration %s has no AppliedAt", s.ID)
+		}
+	}
+
+	// Re-applying must be a no-op: already-applied migrations are skipped,
+	// so this must not fail by trying to re-create the widgets table.
+	if err := m.Apply(); err != nil {
+		t.Fatalf("second Apply (should be a no-op): %v", err)
+	}
+
+	if err := m.Rollback(1); err != nil {
+		t.Fatalf("Rollback(1): %v", err)
+	}
+	statuses, err = m.Status()
+	if err != nil {
+		t.Fatalf("Status after Rollback: %v", err)
+	}
+	if statuses[0].ID != "0001_widgets" || !statuses[0].Applied {
+		t.Errorf("0001_widgets should still be applied after rolling back only 0002, got %+v", statuses[0])
+	}
+	if statuses[1].ID != "0002_widget_color" || statuses[1].Applied {
+		t.Errorf("0002_widget_color should be rolled back, got %+v", statuses[1])
+	}
+
+	if err := m.Apply(); err != nil {
+		t.Fatalf("re-applying 0002 after rollback: %v", err)
+	}
+
+	// A migrator covering 0003, which has no Down step, must refuse to
+	// roll back past it rather than silently skipping it.
+	withThird := NewMigrator(db, all, sqliteDialect{})
+	if err := withThird.Apply(); err != nil {
+		t.Fatalf("applying 0003: %v", err)
+	}
+	if err := withThird.Rollback(2); err == nil {
+		t.Error("Rollback(2) should fail because 0003_widget_note has no Down step")
+	}
+}